@@ -16,15 +16,46 @@ variables by defining a configuration struct:
 		}
 	}
 
-
 A struct tag determines both the name as well as the environment variable name
 for a particular struct field. In the example above, a query timeout can be
 configured by  passing the flag -query.timeout, setting an environment variable
 APP_QUERY_TIMEOUT, or by setting a value on the struct directly. This is also
 the order of precendence.
 
+Adding the "required" modifier to an opt tag, e.g. `opt:"username,required"`,
+fails Load if the field is left at its zero value. Fields can also carry a
+`validate` tag with comma-separated constraints: min=N and max=N bound a
+numeric value, oneof=a|b|c restricts it to one of a fixed set, and any other
+name looks up a Func registered with RegisterValidator and calls it with the
+field's value. Load runs every field's constraints and returns a single
+*ValidationError aggregating every failing *FieldError, rather than stopping
+at the first one.
+
 Supported field types are string, bool, int, uint64, int64, float64, time.Duration,
- url.URL plus any type that implements the flag.Value interface.
+url.URL, []string, []int, map[string]string, net.IP, net.IPNet, time.Time,
+*time.Location, Size, plus any type that implements the flag.Value interface.
+[]string and []int values are split on "," by default; override the
+separator with a `sep:"|"` tag. map[string]string values are always given as
+k=v pairs split on ",", regardless of any sep tag. time.Time values are parsed
+using time.RFC3339 by default; override the layout with an
+`opt-layout:"2006-01-02"` tag.
+
+Struct fields are recursed into, composing a dotted prefix from the enclosing
+field's own tag, so a DB struct{ Host string `opt:"host"` } field tagged
+opt:"db" produces the flag -db.host and the environment variable APP_DB_HOST.
+Embedded structs are flattened under the current prefix unless they carry a
+tag of their own. The naming scheme can be tuned by passing WithSeparator and
+WithEnvPrefix to Load.
+
+Call Usage to print each option's flag name, environment variable, type,
+default value and description in aligned columns, e.g. as a flag.Usage func.
+A field's description comes from its `desc` tag.
 
+Any option's value can be sourced from a file instead of its environment
+variable directly: if <ENVVAR>_FILE is set, its (trimmed) file contents are
+used in place of <ENVVAR>, the convention used for Docker/Kubernetes
+secrets. Tag such a field `secret:"true"` to have its value redacted in
+Usage output, and use Redact to get a copy of config with every secret
+field zeroed out before logging it.
 */
 package structopt
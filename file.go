@@ -0,0 +1,144 @@
+package structopt
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configFlagName is the flag Load registers automatically so callers can
+// point it at a config file without going through LoadFile directly.
+const configFlagName = "config"
+
+// FileError wraps an error encountered while reading or decoding a config
+// file with the path it came from.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string { return fmt.Sprintf("%s: %s", e.Path, e.Err) }
+
+func (e *FileError) Unwrap() error { return e.Err }
+
+// LoadFile behaves like Load, but first decodes a YAML, JSON or TOML
+// config file at path into config. The file format is chosen based on
+// its extension (.yaml, .yml, .json or .toml). Values read from the file
+// become the new struct defaults, so the documented precedence becomes
+// flags > env vars > config file > struct defaults.
+//
+// path may be empty, in which case LoadFile behaves exactly like Load.
+func LoadFile(path, prefix string, config interface{}, flags *flag.FlagSet, opt ...LoadOption) error {
+	if path != "" {
+		if err := readConfigFile(path, config); err != nil {
+			return err
+		}
+	}
+	return Load(prefix, config, flags, opt...)
+}
+
+// readConfigFile decodes the file at path into config, dispatching on its
+// extension.
+func readConfigFile(path string, config interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &FileError{Path: path, Err: err}
+	}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return &FileError{Path: path, Err: err}
+		}
+	case ".json":
+		if err := json.Unmarshal(data, config); err != nil {
+			if line := jsonErrorLine(data, err); line > 0 {
+				err = fmt.Errorf("line %d: %w", line, err)
+			}
+			return &FileError{Path: path, Err: err}
+		}
+	case ".toml":
+		if _, err := toml.Decode(string(data), config); err != nil {
+			return &FileError{Path: path, Err: err}
+		}
+	default:
+		return &FileError{Path: path, Err: fmt.Errorf("unsupported config file extension %q", ext)}
+	}
+	return nil
+}
+
+// jsonErrorLine turns the byte offset on a json.SyntaxError into a
+// 1-based line number, so FileError can point at the offending line.
+func jsonErrorLine(data []byte, err error) int {
+	se, ok := err.(*json.SyntaxError)
+	if !ok {
+		return 0
+	}
+	line := 1
+	for i := int64(0); i < se.Offset && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line++
+		}
+	}
+	return line
+}
+
+// configFileFromArgs scans args for a "-config"/"--config" flag without
+// requiring the rest of the flag set to be defined yet: the config file
+// has to be read, and its values applied to config, before the struct's
+// own flags are registered with their (possibly file-provided) defaults.
+func configFileFromArgs(args []string) string {
+	for i, arg := range args {
+		name := strings.TrimLeft(arg, "-")
+		if name == arg {
+			continue // no leading dash, not a flag
+		}
+		if eq := strings.IndexByte(name, '='); eq >= 0 {
+			if name[:eq] == configFlagName {
+				return name[eq+1:]
+			}
+			continue
+		}
+		if name == configFlagName && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// hasFlagName reports whether one of opts already uses name as its flag
+// name, so Load can skip auto-registering its own "-config" flag rather
+// than panic with "flag redefined" on a struct that has a field tagged
+// opt:"config".
+func hasFlagName(opts []option, name string) bool {
+	for _, o := range opts {
+		if o.flagName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// inferOptionsOrNil runs inferOptions on a zero-valued copy of config's
+// type, so callers can inspect the resulting flag names without
+// allocating into or otherwise disturbing the real config value. Errors
+// are swallowed; inferOptions runs again for real right after and
+// reports them properly.
+func inferOptionsOrNil(prefix string, config interface{}, cfg loadConfig) []option {
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	dummy := reflect.New(v.Elem().Type()).Interface()
+	opts, err := inferOptions(prefix, dummy, cfg)
+	if err != nil {
+		return nil
+	}
+	return opts
+}
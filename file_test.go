@@ -0,0 +1,165 @@
+package structopt
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFileYAML(t *testing.T) {
+	type Config struct {
+		Name    string `opt:"name"`
+		Timeout int    `opt:"timeout"`
+	}
+
+	path := writeTempFile(t, "config-*.yaml", "name: from-yaml\ntimeout: 30\n")
+	os.Unsetenv("APP_NAME")
+	os.Unsetenv("APP_TIMEOUT")
+
+	conf := &Config{}
+	if err := LoadFile(path, prefix, conf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "from-yaml", conf.Name; want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if want, got := 30, conf.Timeout; want != got {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestLoadFileJSON(t *testing.T) {
+	type Config struct {
+		Name string `opt:"name"`
+	}
+
+	path := writeTempFile(t, "config-*.json", `{"name": "from-json"}`)
+	os.Unsetenv("APP_NAME")
+
+	conf := &Config{}
+	if err := LoadFile(path, prefix, conf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "from-json", conf.Name; want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestLoadFileTOML(t *testing.T) {
+	type Config struct {
+		Name string `opt:"name"`
+	}
+
+	path := writeTempFile(t, "config-*.toml", `name = "from-toml"`)
+	os.Unsetenv("APP_NAME")
+
+	conf := &Config{}
+	if err := LoadFile(path, prefix, conf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "from-toml", conf.Name; want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestLoadFilePrecedence(t *testing.T) {
+	type Config struct {
+		Name string `opt:"name"`
+	}
+
+	path := writeTempFile(t, "config-*.yaml", "name: from-file\n")
+	os.Setenv("APP_NAME", "from-env")
+	os.Args = []string{"", "-name", "from-flag"}
+
+	conf := &Config{}
+	fs := flag.NewFlagSet("TestLoadFilePrecedence", flag.ContinueOnError)
+	if err := LoadFile(path, prefix, conf, fs); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "from-flag", conf.Name; want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestLoadFileInvalidJSON(t *testing.T) {
+	type Config struct {
+		Name string `opt:"name"`
+	}
+
+	path := writeTempFile(t, "config-*.json", `{"name": "oops"`)
+	conf := &Config{}
+	err := LoadFile(path, prefix, conf, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	ferr, ok := err.(*FileError)
+	if !ok {
+		t.Fatalf("want *FileError, got %T", err)
+	}
+	if ferr.Path != path {
+		t.Errorf("want path %q, got %q", path, ferr.Path)
+	}
+}
+
+func TestLoadFileUnsupportedExtension(t *testing.T) {
+	type Config struct {
+		Name string `opt:"name"`
+	}
+
+	path := writeTempFile(t, "config-*.ini", "name=nope")
+	conf := &Config{}
+	if err := LoadFile(path, prefix, conf, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestConfigFlag(t *testing.T) {
+	type Config struct {
+		Name string `opt:"name"`
+	}
+
+	path := writeTempFile(t, "config-*.yaml", "name: from-config-flag\n")
+	os.Unsetenv("APP_NAME")
+	os.Args = []string{"", "-config", path}
+
+	conf := &Config{}
+	fs := flag.NewFlagSet("TestConfigFlag", flag.ContinueOnError)
+	if err := Load(prefix, conf, fs); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "from-config-flag", conf.Name; want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestConfigFlagNameCollision(t *testing.T) {
+	type Config struct {
+		Config string `opt:"config"`
+	}
+
+	os.Setenv("APP_CONFIG", "user-value")
+	os.Args = []string{"", "-config", "flag-value"}
+
+	conf := &Config{}
+	fs := flag.NewFlagSet("TestConfigFlagNameCollision", flag.ContinueOnError)
+	if err := Load(prefix, conf, fs); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "flag-value", conf.Config; want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func writeTempFile(t *testing.T, pattern, contents string) string {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Clean(f.Name())
+}
@@ -0,0 +1,164 @@
+package structopt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// defaultSeparator joins nested flag name segments, e.g. "db" + "host" ->
+// "db.host".
+const defaultSeparator = "."
+
+// loadConfig holds the tunable naming scheme used while walking a config
+// struct, set through LoadOption functions passed to Load.
+type loadConfig struct {
+	sep       string
+	envPrefix string
+}
+
+func defaultLoadConfig() loadConfig {
+	return loadConfig{sep: defaultSeparator}
+}
+
+// LoadOption tunes how Load names the flags and environment variables it
+// derives from a config struct.
+type LoadOption func(*loadConfig)
+
+// WithSeparator sets the string used to join nested flag name segments.
+// The default is ".", so a `DB struct{ Host string `opt:"host"` }` field
+// tagged `opt:"db"` produces the flag -db.host.
+func WithSeparator(sep string) LoadOption {
+	return func(c *loadConfig) { c.sep = sep }
+}
+
+// WithEnvPrefix overrides the environment variable prefix, letting it
+// differ from the prefix argument passed to Load (which otherwise also
+// becomes the env prefix).
+func WithEnvPrefix(prefix string) LoadOption {
+	return func(c *loadConfig) { c.envPrefix = prefix }
+}
+
+var valueType = reflect.TypeOf((*value)(nil)).Elem()
+
+// implementsValue reports whether t (or a pointer to t) implements the
+// value interface, meaning it should be treated as a leaf option rather
+// than a struct to recurse into.
+func implementsValue(t reflect.Type) bool {
+	if t.Kind() != reflect.Ptr {
+		t = reflect.PtrTo(t)
+	}
+	return t.Implements(valueType)
+}
+
+// isLeafType reports whether fieldType should be treated as a single
+// option value rather than a struct to recurse into: either because
+// structopt has built-in parsing support for it (isBuiltinLeaf) or
+// because it implements the value interface itself.
+func isLeafType(fieldType reflect.Type) bool {
+	return isBuiltinLeaf(fieldType) || implementsValue(fieldType)
+}
+
+func joinName(prefix, name, sep string) string {
+	switch {
+	case prefix == "":
+		return name
+	case name == "":
+		return prefix
+	default:
+		return prefix + sep + name
+	}
+}
+
+func toEnvSegment(name string) string {
+	seg := strings.ToUpper(name)
+	for _, sep := range flagSeps {
+		seg = strings.ReplaceAll(seg, string(sep), envSep)
+	}
+	return seg
+}
+
+// walkStruct recurses through structType/structVal, appending a leaf
+// option for every field with an "opt" tag. Named struct fields extend
+// the flag/env prefix with their own tag; embedded struct fields are
+// flattened under the current prefix unless they carry a tag of their
+// own. seen guards against fields whose type recursively contains
+// itself.
+func walkStruct(structVal reflect.Value, structType reflect.Type, flagPrefix, envPrefix string, cfg loadConfig, seen map[reflect.Type]bool, opts *[]option) error {
+	for i := 0; i < structType.NumField(); i++ {
+		val := structVal.Field(i)
+		typ := structType.Field(i)
+		if !val.CanSet() {
+			continue
+		}
+		tag := typ.Tag.Get(tagPrefix)
+		fieldType := typ.Type
+		leaf := isLeafType(fieldType)
+
+		if !leaf && fieldType.Kind() == reflect.Ptr && fieldType.Elem().Kind() == reflect.Struct {
+			if len(tag) == 0 && !typ.Anonymous {
+				continue
+			}
+			elemType := fieldType.Elem()
+			if val.IsNil() {
+				val.Set(reflect.New(elemType))
+			}
+			if err := recurseInto(val.Elem(), elemType, typ, tag, flagPrefix, envPrefix, cfg, seen, opts); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !leaf && fieldType.Kind() == reflect.Struct {
+			if len(tag) == 0 && !typ.Anonymous {
+				continue
+			}
+			if err := recurseInto(val, fieldType, typ, tag, flagPrefix, envPrefix, cfg, seen, opts); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if len(tag) == 0 {
+			continue
+		}
+		name, required := splitOptTag(tag)
+		sep := typ.Tag.Get(sepTag)
+		if sep == "" {
+			sep = defaultListSeparator
+		}
+		opt := option{
+			iface:        val.Interface(),
+			ptr:          unsafe.Pointer(val.Addr().Pointer()),
+			flagName:     joinName(flagPrefix, name, cfg.sep),
+			envVar:       joinName(envPrefix, toEnvSegment(name), envSep),
+			flagDesc:     typ.Tag.Get(descTag),
+			required:     required,
+			checks:       parseConstraints(typ.Tag.Get(validateTag)),
+			sep:          sep,
+			layout:       typ.Tag.Get(layoutTag),
+			secret:       typ.Tag.Get(secretTag) == "true",
+			defaultValue: fmt.Sprintf("%v", val.Interface()),
+		}
+		*opts = append(*opts, opt)
+	}
+	return nil
+}
+
+func recurseInto(val reflect.Value, fieldType reflect.Type, typ reflect.StructField, tag, flagPrefix, envPrefix string, cfg loadConfig, seen map[reflect.Type]bool, opts *[]option) error {
+	if seen[fieldType] {
+		return fmt.Errorf("structopt: cycle detected on field %s (%s)", typ.Name, fieldType)
+	}
+
+	childFlagPrefix, childEnvPrefix := flagPrefix, envPrefix
+	if name, _ := splitOptTag(tag); name != "" {
+		childFlagPrefix = joinName(flagPrefix, name, cfg.sep)
+		childEnvPrefix = joinName(envPrefix, toEnvSegment(name), envSep)
+	}
+
+	seen[fieldType] = true
+	err := walkStruct(val, fieldType, childFlagPrefix, childEnvPrefix, cfg, seen, opts)
+	delete(seen, fieldType)
+	return err
+}
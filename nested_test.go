@@ -0,0 +1,110 @@
+package structopt
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+func TestNestedStruct(t *testing.T) {
+	type DB struct {
+		Host string `opt:"host"`
+		Port int    `opt:"port"`
+	}
+	type Config struct {
+		DB DB `opt:"db"`
+	}
+
+	os.Setenv("APP_DB_HOST", "db.internal")
+	os.Setenv("APP_DB_PORT", "5432")
+	os.Args = []string{"", "-db.host", "db.flag", "-db.port", "1"}
+
+	conf := &Config{}
+	fs := flag.NewFlagSet("TestNestedStruct", flag.ContinueOnError)
+	if err := Load(prefix, conf, fs); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "db.flag", conf.DB.Host; want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if want, got := 1, conf.DB.Port; want != got {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestNestedStructPointer(t *testing.T) {
+	type DB struct {
+		Host string `opt:"host"`
+	}
+	type Config struct {
+		DB *DB `opt:"db"`
+	}
+
+	os.Setenv("APP_DB_HOST", "db.internal")
+	conf := &Config{}
+	if err := Load(prefix, conf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if conf.DB == nil {
+		t.Fatal("expected DB to be allocated")
+	}
+	if want, got := "db.internal", conf.DB.Host; want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestEmbeddedStruct(t *testing.T) {
+	type Common struct {
+		Verbose bool `opt:"verbose"`
+	}
+	type Config struct {
+		Common
+		Name string `opt:"name"`
+	}
+
+	os.Setenv("APP_VERBOSE", "true")
+	os.Setenv("APP_NAME", "svc")
+	conf := &Config{}
+	if err := Load(prefix, conf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !conf.Verbose {
+		t.Error("verbose should have been true")
+	}
+	if want, got := "svc", conf.Name; want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestWithSeparatorAndEnvPrefix(t *testing.T) {
+	type DB struct {
+		Host string `opt:"host"`
+	}
+	type Config struct {
+		DB DB `opt:"db"`
+	}
+
+	os.Setenv("APPLICATION_DB_HOST", "db.internal")
+	os.Args = []string{"", "-db_host", "db.flag"}
+
+	conf := &Config{}
+	fs := flag.NewFlagSet("TestWithSeparatorAndEnvPrefix", flag.ContinueOnError)
+	err := Load(prefix, conf, fs, WithSeparator("_"), WithEnvPrefix("APPLICATION"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "db.flag", conf.DB.Host; want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestCycleDetection(t *testing.T) {
+	type Node struct {
+		Next *Node  `opt:"next"`
+		Name string `opt:"name"`
+	}
+	conf := &Node{}
+	if err := Load(prefix, conf, nil); err == nil {
+		t.Fatal("expected a cycle detection error")
+	}
+}
@@ -0,0 +1,71 @@
+package structopt
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// lookupEnvOrFile looks up envVar the way os.LookupEnv does, except that
+// <envVar>_FILE, if set, takes precedence: its value is treated as a path
+// whose (trimmed) contents become the option's value. This is the
+// convention used for Docker/Kubernetes secrets, so a secret never has
+// to be passed as a plaintext env var.
+func lookupEnvOrFile(envVar string) (value string, ok bool, err error) {
+	if path, ok := os.LookupEnv(envVar + fileEnvSuffix); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("reading %s%s: %w", envVar, fileEnvSuffix, err)
+		}
+		return strings.TrimSpace(string(data)), true, nil
+	}
+	v, ok := os.LookupEnv(envVar)
+	return v, ok, nil
+}
+
+// Redact returns a copy of config with every field tagged `secret:"true"`
+// set to its zero value, so the result is safe to log or print. config
+// itself is left untouched.
+func Redact(config interface{}) interface{} {
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return config
+	}
+	cp := reflect.New(v.Elem().Type())
+	cp.Elem().Set(v.Elem())
+	redactStruct(cp.Elem())
+	return cp.Interface()
+}
+
+func redactStruct(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		ft := t.Field(i)
+		if ft.Tag.Get(secretTag) == "true" {
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
+
+		fieldType := ft.Type
+		if isLeafType(fieldType) {
+			continue
+		}
+		if fieldType.Kind() == reflect.Ptr && fieldType.Elem().Kind() == reflect.Struct {
+			if !field.IsNil() {
+				cp := reflect.New(fieldType.Elem())
+				cp.Elem().Set(field.Elem())
+				field.Set(cp)
+				redactStruct(field.Elem())
+			}
+			continue
+		}
+		if fieldType.Kind() == reflect.Struct {
+			redactStruct(field)
+		}
+	}
+}
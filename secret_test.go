@@ -0,0 +1,129 @@
+package structopt
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvFromFile(t *testing.T) {
+	type Config struct {
+		Password string `opt:"password" secret:"true"`
+	}
+
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Unsetenv("APP_PASSWORD")
+	os.Setenv("APP_PASSWORD_FILE", path)
+	t.Cleanup(func() { os.Unsetenv("APP_PASSWORD_FILE") })
+
+	conf := &Config{}
+	if err := Load(prefix, conf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "hunter2", conf.Password; want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestEnvFromFileTakesPrecedenceOverEnv(t *testing.T) {
+	type Config struct {
+		Password string `opt:"password"`
+	}
+
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("from-file"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("APP_PASSWORD", "from-env")
+	os.Setenv("APP_PASSWORD_FILE", path)
+	t.Cleanup(func() { os.Unsetenv("APP_PASSWORD_FILE") })
+
+	conf := &Config{}
+	if err := Load(prefix, conf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "from-file", conf.Password; want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	type Config struct {
+		Name     string `opt:"name"`
+		Password string `opt:"password" secret:"true"`
+	}
+
+	conf := &Config{Name: "svc", Password: "hunter2"}
+	redacted := Redact(conf).(*Config)
+
+	if want, got := "svc", redacted.Name; want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if redacted.Password != "" {
+		t.Errorf("want password to be zeroed, got %q", redacted.Password)
+	}
+	if conf.Password != "hunter2" {
+		t.Errorf("Redact should not mutate the original config, got %q", conf.Password)
+	}
+}
+
+func TestRedactNested(t *testing.T) {
+	type DB struct {
+		Password string `opt:"password" secret:"true"`
+	}
+	type Config struct {
+		DB DB `opt:"db"`
+	}
+
+	conf := &Config{DB: DB{Password: "hunter2"}}
+	redacted := Redact(conf).(*Config)
+	if redacted.DB.Password != "" {
+		t.Errorf("want nested password to be zeroed, got %q", redacted.DB.Password)
+	}
+	if conf.DB.Password != "hunter2" {
+		t.Errorf("Redact should not mutate the original config, got %q", conf.DB.Password)
+	}
+}
+
+func TestRedactNestedPointer(t *testing.T) {
+	type DB struct {
+		Password string `opt:"password" secret:"true"`
+	}
+	type Config struct {
+		DB *DB `opt:"db"`
+	}
+
+	conf := &Config{DB: &DB{Password: "hunter2"}}
+	redacted := Redact(conf).(*Config)
+	if redacted.DB.Password != "" {
+		t.Errorf("want nested password to be zeroed, got %q", redacted.DB.Password)
+	}
+	if conf.DB.Password != "hunter2" {
+		t.Errorf("Redact should not mutate the original config, got %q", conf.DB.Password)
+	}
+}
+
+func TestUsageRedactsSecrets(t *testing.T) {
+	type Config struct {
+		Password string `opt:"password" secret:"true"`
+	}
+
+	conf := &Config{Password: "hunter2"}
+	var buf bytes.Buffer
+	if err := Usage(&buf, prefix, conf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("secret value leaked into usage output:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "<redacted>") {
+		t.Errorf("expected <redacted> marker in output, got:\n%s", buf.String())
+	}
+}
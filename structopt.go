@@ -3,6 +3,7 @@ package structopt
 import (
 	"flag"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"reflect"
@@ -13,63 +14,165 @@ import (
 )
 
 const (
-	flagSeps  = ".-"
-	envSep    = "_"
-	tagPrefix = "opt"
+	flagSeps    = ".-"
+	envSep      = "_"
+	tagPrefix   = "opt"
+	validateTag = "validate"
+	descTag     = "desc"
+	secretTag   = "secret"
+
+	// fileEnvSuffix lets an env var's value be sourced from a file instead,
+	// the convention used for Docker/Kubernetes secrets: <ENVVAR>_FILE, when
+	// set, takes precedence over <ENVVAR> itself.
+	fileEnvSuffix = "_FILE"
 )
 
+// splitOptTag splits an `opt` tag into its flag/env name and whether the
+// "required" modifier was set, e.g. `opt:"query.timeout,required"`.
+func splitOptTag(tag string) (name string, required bool) {
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	for _, mod := range parts[1:] {
+		if strings.TrimSpace(mod) == "required" {
+			required = true
+		}
+	}
+	return name, required
+}
+
 type option struct {
 	iface    interface{}
 	ptr      unsafe.Pointer
 	envVar   string
 	flagName string
 	flagDesc string
+	required bool
+	checks   constraints
+	sep      string // separator for []string and []int values
+	layout   string // time.Time layout, defaults to time.RFC3339
+	secret   bool   // redact this option's value in Usage output
+
+	// defaultValue is the field's value at inference time, i.e. before
+	// env vars or flags are applied, for use in Usage output.
+	defaultValue string
 }
 
 func (o option) set(flags *flag.FlagSet) error {
-	var (
-		iface   = o.iface
-		fromEnv = os.Getenv(o.envVar)
-		err     error
-	)
+	fromEnv, haveEnv, err := lookupEnvOrFile(o.envVar)
+	if err != nil {
+		return err
+	}
+	iface := o.iface
 	switch iface.(type) {
 	case string:
 		sp := (*string)(o.ptr)
-		*sp = fromEnv
+		if haveEnv {
+			*sp = fromEnv
+		}
 		flags.StringVar(sp, o.flagName, *sp, o.flagDesc)
 	case bool:
 		bp := (*bool)(o.ptr)
-		*bp, err = strconv.ParseBool(fromEnv)
+		if haveEnv {
+			*bp, err = strconv.ParseBool(fromEnv)
+		}
 		flags.BoolVar(bp, o.flagName, *bp, o.flagDesc)
 	case int:
 		ip := (*int)(o.ptr)
-		*ip, err = strconv.Atoi(fromEnv)
+		if haveEnv {
+			*ip, err = strconv.Atoi(fromEnv)
+		}
 		flags.IntVar(ip, o.flagName, *ip, o.flagDesc)
 	case uint64:
 		up := (*uint64)(o.ptr)
-		*up, err = strconv.ParseUint(fromEnv, 10, 64)
+		if haveEnv {
+			*up, err = strconv.ParseUint(fromEnv, 10, 64)
+		}
 		flags.Uint64Var(up, o.flagName, *up, o.flagDesc)
 	case int64:
 		ip := (*int64)(o.ptr)
-		*ip, err = strconv.ParseInt(fromEnv, 10, 64)
+		if haveEnv {
+			*ip, err = strconv.ParseInt(fromEnv, 10, 64)
+		}
 		flags.Int64Var(ip, o.flagName, *ip, o.flagDesc)
 	case float64:
 		fp := (*float64)(o.ptr)
-		*fp, err = strconv.ParseFloat(fromEnv, 64)
+		if haveEnv {
+			*fp, err = strconv.ParseFloat(fromEnv, 64)
+		}
 		flags.Float64Var(fp, o.flagName, *fp, o.flagDesc)
 	case time.Duration:
 		dp := (*time.Duration)(o.ptr)
-		*dp, err = time.ParseDuration(fromEnv)
+		if haveEnv {
+			*dp, err = time.ParseDuration(fromEnv)
+		}
 		flags.DurationVar(dp, o.flagName, *dp, o.flagDesc)
 	case url.URL:
 		up := (*url.URL)(o.ptr)
-		var u *url.URL
-		u, err = url.Parse(fromEnv)
-		*up = *u
+		if haveEnv {
+			var u *url.URL
+			u, err = url.Parse(fromEnv)
+			if err == nil {
+				*up = *u
+			}
+		}
 		flags.Var(&urlValue{u: up}, o.flagName, o.flagDesc)
+	case []string:
+		sv := &stringSliceValue{s: (*[]string)(o.ptr), sep: o.sep}
+		if haveEnv {
+			err = sv.Set(fromEnv)
+		}
+		flags.Var(sv, o.flagName, o.flagDesc)
+	case []int:
+		iv := &intSliceValue{s: (*[]int)(o.ptr), sep: o.sep}
+		if haveEnv {
+			err = iv.Set(fromEnv)
+		}
+		flags.Var(iv, o.flagName, o.flagDesc)
+	case map[string]string:
+		mv := &stringMapValue{m: (*map[string]string)(o.ptr)}
+		if haveEnv {
+			err = mv.Set(fromEnv)
+		}
+		flags.Var(mv, o.flagName, o.flagDesc)
+	case net.IP:
+		ipv := &ipValue{ip: (*net.IP)(o.ptr)}
+		if haveEnv {
+			err = ipv.Set(fromEnv)
+		}
+		flags.Var(ipv, o.flagName, o.flagDesc)
+	case net.IPNet:
+		nv := &ipNetValue{n: (*net.IPNet)(o.ptr)}
+		if haveEnv {
+			err = nv.Set(fromEnv)
+		}
+		flags.Var(nv, o.flagName, o.flagDesc)
+	case time.Time:
+		layout := o.layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		tv := &timeValue{t: (*time.Time)(o.ptr), layout: layout}
+		if haveEnv {
+			err = tv.Set(fromEnv)
+		}
+		flags.Var(tv, o.flagName, o.flagDesc)
+	case *time.Location:
+		lv := &locationValue{loc: (**time.Location)(o.ptr)}
+		if haveEnv {
+			err = lv.Set(fromEnv)
+		}
+		flags.Var(lv, o.flagName, o.flagDesc)
+	case Size:
+		szv := &sizeValue{s: (*Size)(o.ptr)}
+		if haveEnv {
+			err = szv.Set(fromEnv)
+		}
+		flags.Var(szv, o.flagName, o.flagDesc)
 	case value:
 		v := iface.(value)
-		err = v.Set(fromEnv)
+		if haveEnv {
+			err = v.Set(fromEnv)
+		}
 		flags.Var(v, o.flagName, o.flagDesc)
 	default:
 		return fmt.Errorf("unsupported field type: %v", reflect.TypeOf(o.iface))
@@ -86,53 +189,81 @@ type value interface {
 	String() string
 }
 
-func inferOptions(prefix string, config interface{}) ([]option, error) {
+func inferOptions(prefix string, config interface{}, cfg loadConfig) ([]option, error) {
 	if v := reflect.ValueOf(config); v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
 		return nil, fmt.Errorf("config must be a pointer to struct, got %v", reflect.TypeOf(config))
 	}
 	structType := reflect.TypeOf(config).Elem()
 	structVal := reflect.ValueOf(config).Elem()
 
-	var opts []option
-	for i := 0; i < structType.NumField(); i++ {
-		val := structVal.Field(i)
-		typ := structType.Field(i)
-		if !val.CanSet() {
-			continue
-		}
-		tag := typ.Tag.Get(tagPrefix)
-		if len(tag) == 0 {
-			continue
-		}
+	envPrefix := cfg.envPrefix
+	if envPrefix == "" {
+		envPrefix = prefix
+	}
 
-		envVar := strings.ToUpper(tag)
-		for _, sep := range flagSeps {
-			strings.ReplaceAll(envVar, string(sep), envSep)
-		}
-		opt := option{
-			iface:    val.Interface(),
-			ptr:      unsafe.Pointer(val.Addr().Pointer()),
-			flagName: tag,
-			envVar:   prefix + envSep + envVar,
-		}
-		opts = append(opts, opt)
+	var opts []option
+	seen := map[reflect.Type]bool{structType: true}
+	if err := walkStruct(structVal, structType, "", envPrefix, cfg, seen, &opts); err != nil {
+		return nil, err
 	}
 	return opts, nil
 }
 
 // Load parses environment varibles for each field on config that has an "opt"
-// tag. If flags is non-nil, it will be used to define command line flags.
-func Load(prefix string, config interface{}, flags *flag.FlagSet) error {
-	opts, err := inferOptions(prefix, config)
-	if err != nil {
-		return err
+// tag. If flags is non-nil, it will be used to define command line flags,
+// and a "-config" flag is registered that reads a YAML, JSON or TOML config
+// file into config before env vars and flags are applied; see LoadFile for
+// the full precedence order.
+// Nested struct fields, and naming of the generated flags and environment
+// variables, can be tuned with the LoadOption functions like WithSeparator
+// and WithEnvPrefix.
+// Load also sets flags.Usage to print the same table as Usage, with
+// defaults taken from config after any -config file has been read into
+// it but before env vars and flags are applied, not the env/flag-resolved
+// values the struct holds once Load returns.
+func Load(prefix string, config interface{}, flags *flag.FlagSet, opt ...LoadOption) error {
+	cfg := defaultLoadConfig()
+	for _, o := range opt {
+		o(&cfg)
 	}
+
 	fs := flags
 	if fs == nil {
 		// Caller doesn't need flags, but create a FlagSet anyway to keep things simple. This
 		// doesn't get parsed.
 		fs = flag.NewFlagSet(prefix, flag.ExitOnError)
 	}
+
+	// A struct field tagged opt:"config" takes priority over the
+	// automatic -config flag below; check for that on a throwaway copy
+	// of config so the check doesn't disturb the value we're about to
+	// read the config file into.
+	ownsConfigFlag := flags != nil && !hasFlagName(inferOptionsOrNil(prefix, config, cfg), configFlagName)
+
+	if ownsConfigFlag {
+		if path := configFileFromArgs(os.Args[1:]); path != "" {
+			if err := readConfigFile(path, config); err != nil {
+				return err
+			}
+		}
+	}
+
+	opts, err := inferOptions(prefix, config, cfg)
+	if err != nil {
+		return err
+	}
+
+	if flags != nil {
+		// Capture opts now, before opt.set() below applies env vars, so
+		// -h prints the struct's own defaults rather than the
+		// env-resolved values.
+		fs.Usage = func() { writeUsageTable(fs.Output(), opts) }
+	}
+
+	if ownsConfigFlag && fs.Lookup(configFlagName) == nil {
+		fs.String(configFlagName, "", "path to a config file (yaml, json or toml), read before env vars and flags")
+	}
+
 	for _, opt := range opts {
 		if err := opt.set(fs); err != nil {
 			return err
@@ -144,6 +275,9 @@ func Load(prefix string, config interface{}, flags *flag.FlagSet) error {
 			return err
 		}
 	}
+	if verr := validateAll(opts); verr != nil {
+		return verr
+	}
 	return nil
 }
 
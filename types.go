@@ -0,0 +1,267 @@
+package structopt
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sepTag    = "sep"
+	layoutTag = "opt-layout"
+)
+
+// defaultListSeparator splits []string and []int values, unless
+// overridden with a `sep` struct tag. map[string]string values always
+// split on ",".
+const defaultListSeparator = ","
+
+var (
+	urlTypeRef      = reflect.TypeOf(url.URL{})
+	ipNetTypeRef    = reflect.TypeOf(net.IPNet{})
+	timeTypeRef     = reflect.TypeOf(time.Time{})
+	locationTypeRef = reflect.TypeOf((*time.Location)(nil))
+)
+
+// isBuiltinLeaf reports whether t is one of the struct- or pointer-shaped
+// types structopt parses as a single option value, rather than recursing
+// into as a nested struct.
+func isBuiltinLeaf(t reflect.Type) bool {
+	switch t {
+	case urlTypeRef, ipNetTypeRef, timeTypeRef, locationTypeRef:
+		return true
+	default:
+		return false
+	}
+}
+
+// Size represents a quantity of bytes, so struct fields like cache or
+// buffer sizes can be configured with human-readable strings such as
+// "100MiB" or "2GB".
+type Size int64
+
+func (s Size) String() string {
+	return strconv.FormatInt(int64(s), 10) + "B"
+}
+
+var sizeRe = regexp.MustCompile(`^([0-9]*\.?[0-9]+)\s*([a-zA-Z]*)$`)
+
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+func parseSize(s string) (Size, error) {
+	m := sizeRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	mult, ok := sizeUnits[strings.ToLower(m[2])]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit %q", m[2])
+	}
+	return Size(n * float64(mult)), nil
+}
+
+type stringSliceValue struct {
+	s   *[]string
+	sep string
+}
+
+func (v *stringSliceValue) String() string {
+	if v.s == nil {
+		return ""
+	}
+	return strings.Join(*v.s, v.sep)
+}
+
+func (v *stringSliceValue) Set(s string) error {
+	if s == "" {
+		*v.s = nil
+		return nil
+	}
+	*v.s = strings.Split(s, v.sep)
+	return nil
+}
+
+type intSliceValue struct {
+	s   *[]int
+	sep string
+}
+
+func (v *intSliceValue) String() string {
+	if v.s == nil {
+		return ""
+	}
+	parts := make([]string, len(*v.s))
+	for i, n := range *v.s {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, v.sep)
+}
+
+func (v *intSliceValue) Set(s string) error {
+	if s == "" {
+		*v.s = nil
+		return nil
+	}
+	parts := strings.Split(s, v.sep)
+	ints := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return fmt.Errorf("invalid int %q: %w", p, err)
+		}
+		ints[i] = n
+	}
+	*v.s = ints
+	return nil
+}
+
+type stringMapValue struct {
+	m *map[string]string
+}
+
+func (v *stringMapValue) String() string {
+	if v.m == nil || *v.m == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(*v.m))
+	for k, val := range *v.m {
+		parts = append(parts, k+"="+val)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (v *stringMapValue) Set(s string) error {
+	m := map[string]string{}
+	if s != "" {
+		for _, kv := range strings.Split(s, ",") {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("invalid map entry %q, want key=value", kv)
+			}
+			m[parts[0]] = parts[1]
+		}
+	}
+	*v.m = m
+	return nil
+}
+
+type ipValue struct {
+	ip *net.IP
+}
+
+func (v *ipValue) String() string {
+	if v.ip == nil || *v.ip == nil {
+		return ""
+	}
+	return v.ip.String()
+}
+
+func (v *ipValue) Set(s string) error {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return fmt.Errorf("invalid IP address %q", s)
+	}
+	*v.ip = ip
+	return nil
+}
+
+type ipNetValue struct {
+	n *net.IPNet
+}
+
+func (v *ipNetValue) String() string {
+	if v.n == nil {
+		return ""
+	}
+	return v.n.String()
+}
+
+func (v *ipNetValue) Set(s string) error {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return err
+	}
+	*v.n = *n
+	return nil
+}
+
+type timeValue struct {
+	t      *time.Time
+	layout string
+}
+
+func (v *timeValue) String() string {
+	if v.t == nil || v.t.IsZero() {
+		return ""
+	}
+	return v.t.Format(v.layout)
+}
+
+func (v *timeValue) Set(s string) error {
+	t, err := time.Parse(v.layout, s)
+	if err != nil {
+		return err
+	}
+	*v.t = t
+	return nil
+}
+
+type locationValue struct {
+	loc **time.Location
+}
+
+func (v *locationValue) String() string {
+	if v.loc == nil || *v.loc == nil {
+		return ""
+	}
+	return (*v.loc).String()
+}
+
+func (v *locationValue) Set(s string) error {
+	loc, err := time.LoadLocation(s)
+	if err != nil {
+		return err
+	}
+	*v.loc = loc
+	return nil
+}
+
+type sizeValue struct {
+	s *Size
+}
+
+func (v *sizeValue) String() string {
+	if v.s == nil {
+		return ""
+	}
+	return v.s.String()
+}
+
+func (v *sizeValue) Set(s string) error {
+	sz, err := parseSize(s)
+	if err != nil {
+		return err
+	}
+	*v.s = sz
+	return nil
+}
@@ -0,0 +1,122 @@
+package structopt
+
+import (
+	"flag"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type TypesConfig struct {
+	Strings  []string          `opt:"strings"`
+	Ints     []int             `opt:"ints"`
+	Pipes    []string          `opt:"pipes" sep:"|"`
+	Tags     map[string]string `opt:"tags"`
+	IP       net.IP            `opt:"ip"`
+	Network  net.IPNet         `opt:"network"`
+	When     time.Time         `opt:"when"`
+	Day      time.Time         `opt:"day" opt-layout:"2006-01-02"`
+	Loc      *time.Location    `opt:"loc"`
+	CacheMax Size              `opt:"cache.max"`
+}
+
+func TestExpandedTypesEnv(t *testing.T) {
+	os.Setenv("APP_STRINGS", "a,b,c")
+	os.Setenv("APP_INTS", "1,2,3")
+	os.Setenv("APP_PIPES", "x|y|z")
+	os.Setenv("APP_TAGS", "env=prod,region=eu")
+	os.Setenv("APP_IP", "192.168.1.1")
+	os.Setenv("APP_NETWORK", "10.0.0.0/8")
+	os.Setenv("APP_WHEN", "2024-01-02T15:04:05Z")
+	os.Setenv("APP_DAY", "2024-01-02")
+	os.Setenv("APP_LOC", "UTC")
+	os.Setenv("APP_CACHE_MAX", "100MiB")
+
+	conf := &TypesConfig{}
+	if err := Load(prefix, conf, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := []string{"a", "b", "c"}, conf.Strings; !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if want, got := []int{1, 2, 3}, conf.Ints; !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if want, got := []string{"x", "y", "z"}, conf.Pipes; !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if want, got := "prod", conf.Tags["env"]; want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if want, got := "192.168.1.1", conf.IP.String(); want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if want, got := "10.0.0.0/8", conf.Network.String(); want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	wantWhen, _ := time.Parse(time.RFC3339, "2024-01-02T15:04:05Z")
+	if !conf.When.Equal(wantWhen) {
+		t.Errorf("want %v, got %v", wantWhen, conf.When)
+	}
+	wantDay, _ := time.Parse("2006-01-02", "2024-01-02")
+	if !conf.Day.Equal(wantDay) {
+		t.Errorf("want %v, got %v", wantDay, conf.Day)
+	}
+	if conf.Loc == nil || conf.Loc.String() != "UTC" {
+		t.Errorf("want UTC, got %v", conf.Loc)
+	}
+	if want, got := Size(100*1<<20), conf.CacheMax; want != got {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestExpandedTypesFlags(t *testing.T) {
+	os.Args = []string{
+		"", "-strings", "d,e", "-ints", "9,8",
+		"-tags", "a=1", "-ip", "10.0.0.1", "-network", "172.16.0.0/12",
+		"-cache.max", "2GB",
+	}
+	conf := &TypesConfig{}
+	fs := flag.NewFlagSet("TestExpandedTypesFlags", flag.ContinueOnError)
+	if err := Load(prefix, conf, fs); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := []string{"d", "e"}, conf.Strings; !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if want, got := []int{9, 8}, conf.Ints; !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if want, got := Size(2e9), conf.CacheMax; want != got {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]Size{
+		"100":    100,
+		"100B":   100,
+		"1KB":    1000,
+		"1KiB":   1024,
+		"2.5MiB": Size(2.5 * (1 << 20)),
+		"1GB":    1e9,
+	}
+	for in, want := range cases {
+		got, err := parseSize(in)
+		if err != nil {
+			t.Fatalf("parseSize(%q): %v", in, err)
+		}
+		if got != want {
+			t.Errorf("parseSize(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestParseSizeInvalid(t *testing.T) {
+	if _, err := parseSize("not-a-size"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
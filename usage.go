@@ -0,0 +1,56 @@
+package structopt
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"text/tabwriter"
+)
+
+// Usage writes an aligned overview of every option on config to w: its
+// flag name, environment variable, type, default value and description.
+// The default value is the one the struct had before Usage ran, so it
+// reflects config's zero value or whatever it was set to beforehand;
+// Usage itself applies no env vars.
+//
+// Usage does not read env vars, flags or a config file; apart from
+// allocating any nil pointer-to-struct fields it recurses into, it leaves
+// config unchanged. Pass the same prefix and LoadOptions used with Load so the
+// flag and env names it prints match.
+func Usage(w io.Writer, prefix string, config interface{}, opt ...LoadOption) error {
+	cfg := defaultLoadConfig()
+	for _, o := range opt {
+		o(&cfg)
+	}
+	opts, err := inferOptions(prefix, config, cfg)
+	if err != nil {
+		return err
+	}
+	return writeUsageTable(w, opts)
+}
+
+// writeUsageTable prints opts in the aligned column format Usage
+// documents. It takes the already-inferred options rather than a config
+// value so Load can wire it up to print the opts captured before env
+// vars or flags were applied, instead of re-inferring from the struct's
+// current (possibly env/flag-mutated) state.
+func writeUsageTable(w io.Writer, opts []option) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FLAG\tENV\tTYPE\tDEFAULT\tDESCRIPTION")
+	for _, o := range opts {
+		desc := o.flagDesc
+		if o.required {
+			if desc != "" {
+				desc += " "
+			}
+			desc += "(required)"
+		}
+		def := o.defaultValue
+		if o.secret {
+			def = "<redacted>"
+		}
+		fmt.Fprintf(tw, "-%s\t%s\t%s\t%s\t%s\n",
+			o.flagName, o.envVar, reflect.TypeOf(o.iface), def, desc)
+	}
+	return tw.Flush()
+}
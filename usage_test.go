@@ -0,0 +1,81 @@
+package structopt
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestUsage(t *testing.T) {
+	type Config struct {
+		Name    string `opt:"name,required" desc:"service name"`
+		Timeout int    `opt:"timeout" desc:"request timeout in seconds" validate:"min=1,max=60"`
+	}
+
+	conf := &Config{Timeout: 30}
+	var buf bytes.Buffer
+	if err := Usage(&buf, prefix, conf); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "-name") || !strings.Contains(out, "APP_NAME") {
+		t.Errorf("expected name flag/env in output, got:\n%s", out)
+	}
+	if !strings.Contains(out, "(required)") {
+		t.Errorf("expected required marker in output, got:\n%s", out)
+	}
+	if !strings.Contains(out, "30") {
+		t.Errorf("expected default value 30 in output, got:\n%s", out)
+	}
+	if !strings.Contains(out, "request timeout in seconds") {
+		t.Errorf("expected description in output, got:\n%s", out)
+	}
+}
+
+func TestUsageDefaultIsPreEnvValue(t *testing.T) {
+	type Config struct {
+		Port int `opt:"port"`
+	}
+
+	conf := &Config{Port: 8080}
+	var buf bytes.Buffer
+	if err := Usage(&buf, prefix, conf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "8080") {
+		t.Errorf("expected struct default 8080 in output, got:\n%s", buf.String())
+	}
+}
+
+func TestUsageViaLoadShowsStructDefault(t *testing.T) {
+	type Config struct {
+		Port int `opt:"port"`
+	}
+
+	os.Setenv("APP_PORT", "9999")
+	os.Args = []string{""}
+	t.Cleanup(func() { os.Unsetenv("APP_PORT") })
+
+	conf := &Config{Port: 8080}
+	fs := flag.NewFlagSet("TestUsageViaLoadShowsStructDefault", flag.ContinueOnError)
+	var buf bytes.Buffer
+	fs.SetOutput(&buf)
+	if err := Load(prefix, conf, fs); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 9999, conf.Port; want != got {
+		t.Fatalf("want env var to win, got %v", got)
+	}
+
+	fs.Usage()
+	out := buf.String()
+	if !strings.Contains(out, "8080") {
+		t.Errorf("expected struct default 8080 in -h output, got:\n%s", out)
+	}
+	if strings.Contains(out, "9999") {
+		t.Errorf("env-resolved value leaked into -h output:\n%s", out)
+	}
+}
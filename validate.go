@@ -0,0 +1,153 @@
+package structopt
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Func is a user-defined validation function. It receives the current
+// value of the field it's attached to and returns an error if the value
+// is invalid. Register one with RegisterValidator and reference it by
+// name from a `validate` tag.
+type Func func(val interface{}) error
+
+var validators = map[string]Func{}
+
+// RegisterValidator registers fn under name so it can be referenced from
+// a `validate` struct tag, e.g. `validate:"evenPort"`.
+func RegisterValidator(name string, fn Func) {
+	validators[name] = fn
+}
+
+// FieldError describes a single validation failure on one option.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (f *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", f.Field, f.Err)
+}
+
+// ValidationError aggregates every FieldError found while validating a
+// config struct, so callers can report all problems in one shot instead
+// of failing on the first one.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (v *ValidationError) Error() string {
+	msgs := make([]string, len(v.Errors))
+	for i, e := range v.Errors {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// constraints holds the parsed contents of a `validate` tag.
+type constraints struct {
+	min   *float64
+	max   *float64
+	oneof []string
+	funcs []string
+}
+
+func parseConstraints(tag string) constraints {
+	var c constraints
+	if tag == "" {
+		return c
+	}
+	for _, part := range strings.Split(tag, ",") {
+		switch {
+		case strings.HasPrefix(part, "min="):
+			if f, err := strconv.ParseFloat(strings.TrimPrefix(part, "min="), 64); err == nil {
+				c.min = &f
+			}
+		case strings.HasPrefix(part, "max="):
+			if f, err := strconv.ParseFloat(strings.TrimPrefix(part, "max="), 64); err == nil {
+				c.max = &f
+			}
+		case strings.HasPrefix(part, "oneof="):
+			c.oneof = strings.Split(strings.TrimPrefix(part, "oneof="), "|")
+		case part != "":
+			c.funcs = append(c.funcs, part)
+		}
+	}
+	return c
+}
+
+// validate checks o's current value against its required flag and
+// validate-tag constraints, returning nil if it passes.
+func (o option) validate() *FieldError {
+	v := reflect.NewAt(reflect.TypeOf(o.iface), o.ptr).Elem()
+
+	if o.required && v.IsZero() {
+		return &FieldError{Field: o.flagName, Err: fmt.Errorf("is required")}
+	}
+
+	if o.checks.min != nil || o.checks.max != nil {
+		if f, ok := asFloat(v); ok {
+			if o.checks.min != nil && f < *o.checks.min {
+				return &FieldError{Field: o.flagName, Err: fmt.Errorf("must be >= %v", *o.checks.min)}
+			}
+			if o.checks.max != nil && f > *o.checks.max {
+				return &FieldError{Field: o.flagName, Err: fmt.Errorf("must be <= %v", *o.checks.max)}
+			}
+		}
+	}
+
+	if len(o.checks.oneof) > 0 {
+		s := fmt.Sprintf("%v", v.Interface())
+		var found bool
+		for _, allowed := range o.checks.oneof {
+			if s == allowed {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &FieldError{Field: o.flagName, Err: fmt.Errorf("must be one of %s", strings.Join(o.checks.oneof, ", "))}
+		}
+	}
+
+	for _, name := range o.checks.funcs {
+		fn, ok := validators[name]
+		if !ok {
+			continue
+		}
+		if err := fn(v.Interface()); err != nil {
+			return &FieldError{Field: o.flagName, Err: err}
+		}
+	}
+	return nil
+}
+
+func asFloat(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// validateAll runs validate on every option and aggregates the failures
+// into a single ValidationError, returning nil if none failed.
+func validateAll(opts []option) error {
+	var verr ValidationError
+	for _, opt := range opts {
+		if ferr := opt.validate(); ferr != nil {
+			verr.Errors = append(verr.Errors, ferr)
+		}
+	}
+	if len(verr.Errors) == 0 {
+		return nil
+	}
+	return &verr
+}
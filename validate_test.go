@@ -0,0 +1,109 @@
+package structopt
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"testing"
+)
+
+func TestRequired(t *testing.T) {
+	type Test struct {
+		Name string `opt:"name,required"`
+	}
+	os.Unsetenv("APP_NAME")
+	os.Args = []string{""}
+	test := Test{}
+	err := Load(prefix, &test, flag.NewFlagSet("TestRequired", flag.ContinueOnError))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("want *ValidationError, got %T", err)
+	}
+	if want, got := 1, len(verr.Errors); want != got {
+		t.Fatalf("want %d errors, got %d", want, got)
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	type Test struct {
+		Timeout int `opt:"timeout" validate:"min=1,max=60"`
+	}
+	os.Setenv("APP_TIMEOUT", "100")
+	test := Test{}
+	err := Load(prefix, &test, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, ok := err.(*ValidationError); !ok {
+		t.Fatalf("want *ValidationError, got %T", err)
+	}
+}
+
+func TestOneof(t *testing.T) {
+	type Test struct {
+		Env string `opt:"env" validate:"oneof=dev|staging|prod"`
+	}
+	os.Setenv("APP_ENV", "qa")
+	test := Test{}
+	err := Load(prefix, &test, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	os.Setenv("APP_ENV", "staging")
+	test = Test{}
+	if err := Load(prefix, &test, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRegisteredValidator(t *testing.T) {
+	RegisterValidator("even", func(val interface{}) error {
+		if val.(int)%2 != 0 {
+			return errOdd
+		}
+		return nil
+	})
+
+	type Test struct {
+		Port int `opt:"port" validate:"even"`
+	}
+	os.Setenv("APP_PORT", "1234")
+	test := Test{}
+	if err := Load(prefix, &test, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	os.Setenv("APP_PORT", "1235")
+	test = Test{}
+	err := Load(prefix, &test, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestValidationErrorAggregates(t *testing.T) {
+	type Test struct {
+		Name    string `opt:"name,required"`
+		Timeout int    `opt:"timeout" validate:"min=1"`
+	}
+	os.Unsetenv("APP_NAME")
+	os.Setenv("APP_TIMEOUT", "0")
+	test := Test{}
+	err := Load(prefix, &test, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("want *ValidationError, got %T", err)
+	}
+	if want, got := 2, len(verr.Errors); want != got {
+		t.Fatalf("want %d errors, got %d", want, got)
+	}
+}
+
+var errOdd = errors.New("must be even")